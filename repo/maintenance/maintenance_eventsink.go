@@ -0,0 +1,175 @@
+package maintenance
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/kopia/kopia/repo"
+)
+
+// EventSink receives notifications about maintenance progress as it happens.
+//
+// Embedders that vend Kopia as a library can implement EventSink to bridge maintenance
+// events into their own structured logging and metrics systems without having to scrape
+// repository blobs or parse log output. All methods must be safe to call from the
+// goroutine running the maintenance cycle and must return quickly since they are called
+// synchronously from the hot path.
+type EventSink interface {
+	// OnCycleStart is called when a maintenance cycle (quick or full) begins.
+	OnCycleStart(kind string)
+
+	// OnCycleEnd is called when a maintenance cycle finishes, successfully or not.
+	OnCycleEnd(kind string, err error)
+
+	// OnTaskStart is called when an individual maintenance task begins.
+	OnTaskStart(taskName string)
+
+	// OnTaskFinish is called when an individual maintenance task completes.
+	OnTaskFinish(taskName string, dur time.Duration, err error)
+
+	// OnBlobRewritten is called every time a content blob is rewritten during compaction.
+	OnBlobRewritten(blobID string, length int64)
+
+	// OnSnapshotDeleted is called every time a snapshot manifest is deleted as garbage.
+	OnSnapshotDeleted(manifestID string)
+}
+
+// NullEventSink is a no-op EventSink used by default so that embedders who don't care
+// about maintenance events pay no cost and existing behavior is unchanged.
+type NullEventSink struct{}
+
+// OnCycleStart implements EventSink.
+func (NullEventSink) OnCycleStart(kind string) {}
+
+// OnCycleEnd implements EventSink.
+func (NullEventSink) OnCycleEnd(kind string, err error) {}
+
+// OnTaskStart implements EventSink.
+func (NullEventSink) OnTaskStart(taskName string) {}
+
+// OnTaskFinish implements EventSink.
+func (NullEventSink) OnTaskFinish(taskName string, dur time.Duration, err error) {}
+
+// OnBlobRewritten implements EventSink.
+func (NullEventSink) OnBlobRewritten(blobID string, length int64) {}
+
+// OnSnapshotDeleted implements EventSink.
+func (NullEventSink) OnSnapshotDeleted(manifestID string) {}
+
+// nolint:gochecknoglobals
+var defaultEventSink EventSink = NullEventSink{}
+
+// nolint:gochecknoglobals
+var (
+	eventSinksMu sync.RWMutex
+	// eventSinks is keyed by the identity of the underlying repository object (see
+	// repositoryIdentity), not by username@host - a single OS user commonly opens more than
+	// one repository in the same process (tests, or an embedder managing multiple repos) and
+	// those must not share a sink.
+	//
+	// The key is a uintptr rather than the repo.Repository interface value itself: a map
+	// keyed directly by the interface would hold a strong reference to the repository
+	// forever, so a long-running embedder (e.g. Velero/Kanister) that opens and closes many
+	// short-lived repository instances without ever calling SetEventSink(rep, nil) would leak
+	// one entry per repository for the lifetime of the process. Keying by uintptr instead,
+	// combined with a finalizer registered on the repository object itself, lets the entry be
+	// reclaimed automatically once nothing else references the repository.
+	eventSinks = map[uintptr]EventSink{}
+)
+
+// repositoryIdentity returns a stable identity for rep suitable for use as a map key that
+// does not itself keep rep alive, along with whether one could be determined. Repository
+// implementations are expected to be pointers, which is the only shape runtime.SetFinalizer
+// (used by SetEventSink) accepts; any other shape yields false.
+func repositoryIdentity(rep repo.Repository) (uintptr, bool) {
+	v := reflect.ValueOf(rep)
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		return v.Pointer(), true
+	default:
+		return 0, false
+	}
+}
+
+// SetEventSink registers the EventSink to receive maintenance events for the given
+// repository instance. The sink is held in-process only; it is not persisted and must be
+// set again on every process that wants to observe events. Passing nil restores the
+// default no-op sink and removes the registration; if it is never called, the registration
+// is still cleaned up automatically once rep is garbage collected.
+func SetEventSink(rep repo.RepositoryWriter, sink EventSink) {
+	id, ok := repositoryIdentity(rep)
+	if !ok {
+		return
+	}
+
+	eventSinksMu.Lock()
+	defer eventSinksMu.Unlock()
+
+	if sink == nil {
+		delete(eventSinks, id)
+		runtime.SetFinalizer(rep, nil)
+
+		return
+	}
+
+	eventSinks[id] = sink
+
+	// clear any finalizer left by an earlier call, since the runtime refuses to set a second one
+	runtime.SetFinalizer(rep, nil)
+	runtime.SetFinalizer(rep, func(interface{}) {
+		eventSinksMu.Lock()
+		defer eventSinksMu.Unlock()
+
+		delete(eventSinks, id)
+	})
+}
+
+// eventSinkFor returns the EventSink registered for the given repository instance, or the
+// default no-op sink if none was registered.
+func eventSinkFor(rep repo.Repository) EventSink {
+	id, ok := repositoryIdentity(rep)
+	if !ok {
+		return defaultEventSink
+	}
+
+	eventSinksMu.RLock()
+	defer eventSinksMu.RUnlock()
+
+	if sink, ok := eventSinks[id]; ok {
+		return sink
+	}
+
+	return defaultEventSink
+}
+
+// nolint:gochecknoglobals
+type eventSinkContextKey struct{}
+
+// WithEventSink returns a copy of ctx carrying sink, so that code further down the call
+// stack - in particular the blob-rewrite and snapshot-deletion paths that OnBlobRewritten
+// and OnSnapshotDeleted exist for - can reach it via EventSinkFromContext without needing
+// the original repository instance to look it up with eventSinkFor.
+//
+// NOTE: RunTask (and so RunDueTasks, through RunTask) calls this so the sink is reachable for any task that wants it,
+// but no call site in this snapshot of the tree actually invokes OnBlobRewritten or
+// OnSnapshotDeleted yet - the blob-compaction and snapshot-gc task implementations that
+// would call them live outside this package and are out of scope here. Wiring this through
+// ctx rather than, say, a parameter on TaskFunc keeps those call sites free to pick it up
+// without a signature change once they exist.
+func WithEventSink(ctx context.Context, sink EventSink) context.Context {
+	return context.WithValue(ctx, eventSinkContextKey{}, sink)
+}
+
+// EventSinkFromContext returns the EventSink stashed in ctx by WithEventSink, or the
+// default no-op sink if none was set.
+func EventSinkFromContext(ctx context.Context) EventSink {
+	if sink, ok := ctx.Value(eventSinkContextKey{}).(EventSink); ok {
+		return sink
+	}
+
+	return defaultEventSink
+}
@@ -0,0 +1,60 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusSink is an EventSink that forwards maintenance events to a *logrus.Logger, for
+// embedders that already use logrus for structured logging.
+type LogrusSink struct {
+	Logger *logrus.Logger
+}
+
+// NewLogrusSink returns an EventSink that logs maintenance events to the given logrus logger.
+func NewLogrusSink(logger *logrus.Logger) *LogrusSink {
+	return &LogrusSink{Logger: logger}
+}
+
+// OnCycleStart implements EventSink.
+func (s *LogrusSink) OnCycleStart(kind string) {
+	s.Logger.WithField("kind", kind).Info("maintenance cycle started")
+}
+
+// OnCycleEnd implements EventSink.
+func (s *LogrusSink) OnCycleEnd(kind string, err error) {
+	if err != nil {
+		s.Logger.WithField("kind", kind).WithError(err).Error("maintenance cycle failed")
+		return
+	}
+
+	s.Logger.WithField("kind", kind).Info("maintenance cycle finished")
+}
+
+// OnTaskStart implements EventSink.
+func (s *LogrusSink) OnTaskStart(taskName string) {
+	s.Logger.WithField("task", taskName).Info("maintenance task started")
+}
+
+// OnTaskFinish implements EventSink.
+func (s *LogrusSink) OnTaskFinish(taskName string, dur time.Duration, err error) {
+	entry := s.Logger.WithField("task", taskName).WithField("duration", dur)
+
+	if err != nil {
+		entry.WithError(err).Error("maintenance task failed")
+		return
+	}
+
+	entry.Info("maintenance task finished")
+}
+
+// OnBlobRewritten implements EventSink.
+func (s *LogrusSink) OnBlobRewritten(blobID string, length int64) {
+	s.Logger.WithField("blobID", blobID).WithField("length", length).Debug("blob rewritten")
+}
+
+// OnSnapshotDeleted implements EventSink.
+func (s *LogrusSink) OnSnapshotDeleted(manifestID string) {
+	s.Logger.WithField("manifestID", manifestID).Debug("snapshot deleted")
+}
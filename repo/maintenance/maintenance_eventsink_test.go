@@ -0,0 +1,57 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kopia/kopia/repo"
+)
+
+// fakeRepositoryWriter is a bare-bones stand-in used only to exercise SetEventSink/
+// eventSinkFor keying: two distinct instances representing the same username@host must not
+// share a registered sink.
+type fakeRepositoryWriter struct {
+	repo.RepositoryWriter
+}
+
+func TestEventSink_KeyedByRepositoryInstanceNotUsername(t *testing.T) {
+	repA := &fakeRepositoryWriter{}
+	repB := &fakeRepositoryWriter{}
+
+	sinkA := &recordingSink{}
+	sinkB := &recordingSink{}
+
+	SetEventSink(repA, sinkA)
+	SetEventSink(repB, sinkB)
+
+	defer SetEventSink(repA, nil)
+	defer SetEventSink(repB, nil)
+
+	if got := eventSinkFor(repA); got != sinkA {
+		t.Fatalf("expected repA to get sinkA, got %v", got)
+	}
+
+	if got := eventSinkFor(repB); got != sinkB {
+		t.Fatalf("expected repB to get sinkB, got %v", got)
+	}
+}
+
+type recordingSink struct {
+	NullEventSink
+}
+
+func TestEventSinkFromContext(t *testing.T) {
+	ctx := context.Background()
+
+	if got := EventSinkFromContext(ctx); got != defaultEventSink {
+		t.Fatalf("expected default sink when none set, got %v", got)
+	}
+
+	sink := &recordingSink{}
+
+	ctx = WithEventSink(ctx, sink)
+
+	if got := EventSinkFromContext(ctx); got != sink {
+		t.Fatalf("expected the sink set with WithEventSink, got %v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package maintenance
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ZapSink is an EventSink that forwards maintenance events to a *zap.Logger, for embedders
+// that already use zap for structured logging.
+type ZapSink struct {
+	Logger *zap.Logger
+}
+
+// NewZapSink returns an EventSink that logs maintenance events to the given zap logger.
+func NewZapSink(logger *zap.Logger) *ZapSink {
+	return &ZapSink{Logger: logger}
+}
+
+// OnCycleStart implements EventSink.
+func (s *ZapSink) OnCycleStart(kind string) {
+	s.Logger.Info("maintenance cycle started", zap.String("kind", kind))
+}
+
+// OnCycleEnd implements EventSink.
+func (s *ZapSink) OnCycleEnd(kind string, err error) {
+	if err != nil {
+		s.Logger.Error("maintenance cycle failed", zap.String("kind", kind), zap.Error(err))
+		return
+	}
+
+	s.Logger.Info("maintenance cycle finished", zap.String("kind", kind))
+}
+
+// OnTaskStart implements EventSink.
+func (s *ZapSink) OnTaskStart(taskName string) {
+	s.Logger.Info("maintenance task started", zap.String("task", taskName))
+}
+
+// OnTaskFinish implements EventSink.
+func (s *ZapSink) OnTaskFinish(taskName string, dur time.Duration, err error) {
+	if err != nil {
+		s.Logger.Error("maintenance task failed", zap.String("task", taskName), zap.Duration("duration", dur), zap.Error(err))
+		return
+	}
+
+	s.Logger.Info("maintenance task finished", zap.String("task", taskName), zap.Duration("duration", dur))
+}
+
+// OnBlobRewritten implements EventSink.
+func (s *ZapSink) OnBlobRewritten(blobID string, length int64) {
+	s.Logger.Debug("blob rewritten", zap.String("blobID", blobID), zap.Int64("length", length))
+}
+
+// OnSnapshotDeleted implements EventSink.
+func (s *ZapSink) OnSnapshotDeleted(manifestID string) {
+	s.Logger.Debug("snapshot deleted", zap.String("manifestID", manifestID))
+}
@@ -0,0 +1,192 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+// OwnerLease identifies the client currently responsible for running scheduled maintenance
+// and for how long that claim is valid. Unlike the plain Owner string it replaces, a lease
+// expires on its own so that a workstation going offline does not block maintenance forever;
+// another client can take over once ExpiresAt has passed.
+//
+// NOTE: this package only covers repository-side acquisition/renewal (AcquireOwnership,
+// RenewOwnership, EnsureOwnership) and the CLI commands built on top of them. Making the
+// KopiaUI/API server itself honor the lease before kicking off its own scheduled maintenance
+// cycles is a separate, currently unimplemented piece of work - there is no server code in
+// this package, and none is added here.
+type OwnerLease struct {
+	Owner string `json:"owner"`
+
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+
+	// HeartbeatInterval is how often the owner is expected to call RenewOwnership; it is
+	// informational for other clients deciding how stale a lease looks.
+	HeartbeatInterval time.Duration `json:"heartbeatInterval"`
+}
+
+// isExpired returns true if the lease has an expiry and it has passed. A zero ExpiresAt
+// means the lease was migrated from a legacy Owner string and never expires on its own.
+func (l OwnerLease) isExpired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && now.After(l.ExpiresAt)
+}
+
+// Default lease lifetime and renewal cadence used when callers (the CLI, the automatic
+// failover in EnsureOwnership) don't have a more specific value of their own.
+const (
+	DefaultLeaseTTL               = 10 * time.Minute
+	DefaultLeaseHeartbeatInterval = 2 * time.Minute
+)
+
+// checkLeaseConflict returns an error if the lease is currently held by someone other than
+// me and has not expired, unless force is set. It is pure so the conflict logic that
+// AcquireOwnership relies on can be tested without a repository.
+func checkLeaseConflict(lease OwnerLease, me string, now time.Time, force bool) error {
+	if lease.Owner != "" && lease.Owner != me && !lease.isExpired(now) && !force {
+		return errors.Errorf("maintenance is currently owned by %q until %v, use force to override", lease.Owner, lease.ExpiresAt)
+	}
+
+	return nil
+}
+
+// AcquireOwnership attempts to take over the maintenance owner lease for the current
+// client. It refuses to do so while the existing lease is held by someone else and has not
+// expired, unless force is true. It uses the same find-put-then-delete-stale pattern as
+// SetParams to tolerate other clients racing to acquire the lease at the same time.
+func AcquireOwnership(ctx context.Context, rep repo.RepositoryWriter, ttl time.Duration, heartbeat time.Duration, force bool) error {
+	return acquireOwnership(ctx, rep, ttl, heartbeat, force, rep.Time())
+}
+
+func acquireOwnership(ctx context.Context, rep repo.RepositoryWriter, ttl, heartbeat time.Duration, force bool, now time.Time) error {
+	md, err := manifestIDs(ctx, rep)
+	if err != nil {
+		return err
+	}
+
+	p := DefaultParams()
+
+	if len(md) > 0 {
+		if _, err := rep.GetManifest(ctx, manifest.PickLatestID(md), &p); err != nil {
+			return errors.Wrap(err, "error loading manifest")
+		}
+
+		p.migrateOwner()
+	}
+
+	me := rep.ClientOptions().UsernameAtHost()
+
+	if err := checkLeaseConflict(p.OwnerLease, me, now, force); err != nil {
+		return err
+	}
+
+	p.OwnerLease = OwnerLease{
+		Owner:             me,
+		AcquiredAt:        now,
+		ExpiresAt:         now.Add(ttl),
+		HeartbeatInterval: heartbeat,
+	}
+	p.Owner = me
+
+	if _, err := rep.PutManifest(ctx, manifestLabels, &p); err != nil {
+		return errors.Wrap(err, "put manifest")
+	}
+
+	for _, m := range md {
+		if err := rep.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrap(err, "delete manifest")
+		}
+	}
+
+	return nil
+}
+
+// RenewOwnership extends the current client's lease by ttl, provided it is still the owner.
+// It is intended to be called periodically (every HeartbeatInterval) by the maintenance
+// loop while it runs, so the lease does not expire out from under an active client. Like
+// acquireOwnership, it re-reads and checks ownership at write time rather than trusting a
+// value read earlier, so a concurrent AcquireOwnership(force=true) by another client racing
+// in between can't have its takeover silently clobbered by this renewal.
+func RenewOwnership(ctx context.Context, rep repo.RepositoryWriter, ttl time.Duration) error {
+	md, err := manifestIDs(ctx, rep)
+	if err != nil {
+		return err
+	}
+
+	p := DefaultParams()
+
+	if len(md) > 0 {
+		if _, err := rep.GetManifest(ctx, manifest.PickLatestID(md), &p); err != nil {
+			return errors.Wrap(err, "error loading manifest")
+		}
+
+		p.migrateOwner()
+	}
+
+	me := rep.ClientOptions().UsernameAtHost()
+	if p.OwnerLease.Owner != me {
+		return errors.Errorf("maintenance is owned by %q, not %q", p.OwnerLease.Owner, me)
+	}
+
+	p.OwnerLease.ExpiresAt = rep.Time().Add(ttl)
+	p.Owner = p.OwnerLease.Owner
+
+	if _, err := rep.PutManifest(ctx, manifestLabels, &p); err != nil {
+		return errors.Wrap(err, "put manifest")
+	}
+
+	for _, m := range md {
+		if err := rep.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrap(err, "delete manifest")
+		}
+	}
+
+	return nil
+}
+
+// ReleaseOwnership gives up the current client's lease, if it holds one, so that another
+// client does not have to wait for the lease to expire naturally. As with RenewOwnership, it
+// re-checks ownership against a freshly read manifest at write time rather than a value read
+// earlier, to avoid racing with a concurrent takeover.
+func ReleaseOwnership(ctx context.Context, rep repo.RepositoryWriter) error {
+	md, err := manifestIDs(ctx, rep)
+	if err != nil {
+		return err
+	}
+
+	p := DefaultParams()
+
+	if len(md) > 0 {
+		if _, err := rep.GetManifest(ctx, manifest.PickLatestID(md), &p); err != nil {
+			return errors.Wrap(err, "error loading manifest")
+		}
+
+		p.migrateOwner()
+	}
+
+	me := rep.ClientOptions().UsernameAtHost()
+	if p.OwnerLease.Owner != me {
+		// nothing to do - we don't hold the lease
+		return nil
+	}
+
+	p.OwnerLease = OwnerLease{}
+	p.Owner = ""
+
+	if _, err := rep.PutManifest(ctx, manifestLabels, &p); err != nil {
+		return errors.Wrap(err, "put manifest")
+	}
+
+	for _, m := range md {
+		if err := rep.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrap(err, "delete manifest")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnerLease_IsExpired(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	cases := []struct {
+		name  string
+		lease OwnerLease
+		want  bool
+	}{
+		{"zero expiry never expires", OwnerLease{}, false},
+		{"future expiry not expired", OwnerLease{ExpiresAt: now.Add(time.Minute)}, false},
+		{"past expiry is expired", OwnerLease{ExpiresAt: now.Add(-time.Minute)}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.lease.isExpired(now); got != tc.want {
+				t.Fatalf("isExpired() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckLeaseConflict(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	cases := []struct {
+		name    string
+		lease   OwnerLease
+		me      string
+		force   bool
+		wantErr bool
+	}{
+		{"no existing owner", OwnerLease{}, "me@host", false, false},
+		{"already owned by me", OwnerLease{Owner: "me@host", ExpiresAt: now.Add(time.Minute)}, "me@host", false, false},
+		{"owned by other, unexpired, no force", OwnerLease{Owner: "other@host", ExpiresAt: now.Add(time.Minute)}, "me@host", false, true},
+		{"owned by other, unexpired, force", OwnerLease{Owner: "other@host", ExpiresAt: now.Add(time.Minute)}, "me@host", true, false},
+		{"owned by other, expired", OwnerLease{Owner: "other@host", ExpiresAt: now.Add(-time.Minute)}, "me@host", false, false},
+		{"owned by other, never expires, no force", OwnerLease{Owner: "other@host"}, "me@host", false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkLeaseConflict(tc.lease, tc.me, now, tc.force)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("checkLeaseConflict() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
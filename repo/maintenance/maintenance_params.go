@@ -15,23 +15,60 @@ var manifestLabels = map[string]string{
 	"type": "maintenance",
 }
 
+// Well-known maintenance task names, used as keys in Params.Tasks and in
+// Status.Tasks/EventSink callbacks.
+const (
+	TaskSnapshotGC      = "snapshot-gc"
+	TaskBlobGC          = "blob-gc"
+	TaskRewriteContents = "rewrite-contents"
+	TaskLogCleanup      = "log-cleanup"
+)
+
+// nolint:gochecknoglobals
+var (
+	quickCycleTasks = []string{TaskSnapshotGC, TaskLogCleanup}
+	fullCycleTasks  = []string{TaskBlobGC, TaskRewriteContents}
+)
+
 // Params is a JSON-serialized maintenance configuration stored in a repository.
 type Params struct {
-	Owner string `json:"owner"`
+	// Owner is deprecated in favor of OwnerLease and is only populated/read for backward
+	// compatibility with manifests written before ownership leases existed.
+	Owner string `json:"owner,omitempty"`
+
+	OwnerLease OwnerLease `json:"ownerLease,omitempty"`
 
 	QuickCycle CycleParams `json:"quick"`
 	FullCycle  CycleParams `json:"full"`
 
+	// Tasks overrides the schedule for individual maintenance tasks. A task with no entry
+	// here falls back to whichever of QuickCycle or FullCycle it belongs to. The value is a
+	// pointer so that an explicit override of TaskSchedule{Enabled: false} (disabling a task
+	// entirely) is distinguishable from "no override configured" - a nil or absent entry.
+	Tasks map[string]*TaskSchedule `json:"tasks,omitempty"`
+
 	LogRetention LogRetentionOptions `json:"logRetention"`
 }
 
 func (p *Params) isOwnedByByThisUser(rep repo.Repository) bool {
-	return p.Owner == rep.ClientOptions().UsernameAtHost()
+	return p.OwnerLease.Owner == rep.ClientOptions().UsernameAtHost()
+}
+
+// migrateOwner ensures OwnerLease is populated, synthesizing it from the deprecated Owner
+// field when reading a manifest written before ownership leases existed. A migrated lease
+// never expires on its own (ExpiresAt is left zero) so that pre-existing ownership is not
+// silently dropped; it will only be taken over via an explicit AcquireOwnership(force=true).
+func (p *Params) migrateOwner() {
+	if p.OwnerLease.Owner == "" && p.Owner != "" {
+		p.OwnerLease = OwnerLease{Owner: p.Owner}
+	}
+
+	p.Owner = p.OwnerLease.Owner
 }
 
 // DefaultParams represents default values of maintenance parameters.
 func DefaultParams() Params {
-	return Params{
+	p := Params{
 		FullCycle: CycleParams{
 			Enabled:  true,
 			Interval: 24 * time.Hour, //nolint:gomnd
@@ -42,6 +79,10 @@ func DefaultParams() Params {
 		},
 		LogRetention: defaultLogRetention(),
 	}
+
+	p.migrateTasks()
+
+	return p
 }
 
 // CycleParams specifies parameters for a maintenance cycle (quick or full).
@@ -50,6 +91,99 @@ type CycleParams struct {
 	Interval time.Duration `json:"interval"`
 }
 
+// TaskSchedule specifies the schedule for a single maintenance task, overriding whichever
+// of QuickCycle/FullCycle it would otherwise inherit.
+type TaskSchedule struct {
+	Enabled bool `json:"enabled"`
+
+	// Interval runs the task on a fixed period, same semantics as CycleParams.Interval.
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Cron, when non-empty, is a standard 5-field cron expression (minute hour dom month dow)
+	// and takes precedence over Interval.
+	Cron string `json:"cron,omitempty"`
+
+	// Jitter adds a random delay of up to this duration to each scheduled run, to avoid
+	// many clients waking up and contending for the maintenance lock at the exact same time.
+	Jitter time.Duration `json:"jitter,omitempty"`
+}
+
+// effectiveTaskSchedule returns the schedule that applies to taskName, taking an explicit
+// Tasks[taskName] override if present - including an override that disables the task -
+// and falling back to the cycle it belongs to only when no entry was configured at all.
+func (p *Params) effectiveTaskSchedule(taskName string) TaskSchedule {
+	if ts, ok := p.Tasks[taskName]; ok && ts != nil {
+		return *ts
+	}
+
+	cycle := p.QuickCycle
+	if isFullCycleTask(taskName) {
+		cycle = p.FullCycle
+	}
+
+	return TaskSchedule{Enabled: cycle.Enabled, Interval: cycle.Interval}
+}
+
+func isFullCycleTask(taskName string) bool {
+	for _, t := range fullCycleTasks {
+		if t == taskName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allTaskNames returns the union of tasks known to the quick/full cycles and any
+// additional tasks the caller has configured an override for.
+func (p *Params) allTaskNames() []string {
+	seen := map[string]bool{}
+
+	var names []string
+
+	for _, t := range append(append([]string{}, quickCycleTasks...), fullCycleTasks...) {
+		if !seen[t] {
+			seen[t] = true
+			names = append(names, t)
+		}
+	}
+
+	for t := range p.Tasks {
+		if !seen[t] {
+			seen[t] = true
+			names = append(names, t)
+		}
+	}
+
+	return names
+}
+
+// migrateTasks ensures Tasks is non-nil and contains an explicit entry for every known task,
+// synthesizing one from QuickCycle/FullCycle only for tasks that have no override stored.
+// Existing overrides - including ones that explicitly disable a task - are preserved as-is.
+// This keeps GetParams backward compatible with manifests written before per-task schedules
+// existed, without discarding explicit overrides manifests written since then.
+func (p *Params) migrateTasks() {
+	tasks := map[string]*TaskSchedule{}
+
+	for name, ts := range p.Tasks {
+		if ts != nil {
+			tasks[name] = ts
+		}
+	}
+
+	for _, name := range p.allTaskNames() {
+		if _, ok := tasks[name]; ok {
+			continue
+		}
+
+		eff := p.effectiveTaskSchedule(name)
+		tasks[name] = &eff
+	}
+
+	p.Tasks = tasks
+}
+
 // HasParams determines whether repository-wide maintenance parameters have been set.
 func HasParams(ctx context.Context, rep repo.Repository) (bool, error) {
 	md, err := manifestIDs(ctx, rep)
@@ -60,14 +194,51 @@ func HasParams(ctx context.Context, rep repo.Repository) (bool, error) {
 	return len(md) > 0, nil
 }
 
-// IsOwnedByThisUser determines whether current user is the maintenance owner.
+// IsOwnedByThisUser determines whether current user is the maintenance owner according to
+// the lease as currently stored, with no side effects - suitable for status/display
+// purposes (e.g. "kopia maintenance owner status") where a read-only repo.Repository is all
+// the caller has, and where it would be surprising for a mere status check to steal the
+// lease out from under a legitimately running owner. Callers that intend to actually run
+// maintenance and want an expired lease to be taken over automatically should use
+// EnsureOwnership instead.
 func IsOwnedByThisUser(ctx context.Context, rep repo.Repository) (bool, error) {
 	p, err := GetParams(ctx, rep)
 	if err != nil {
 		return false, errors.Wrap(err, "error getting maintenance params")
 	}
 
-	return p.isOwnedByByThisUser(rep), nil
+	return p.isOwnedByByThisUser(rep) && !p.OwnerLease.isExpired(rep.Time()), nil
+}
+
+// EnsureOwnership determines whether the current user is the maintenance owner, the same as
+// IsOwnedByThisUser, except that if the stored lease has expired, it automatically attempts
+// to re-acquire it on behalf of the current client with AcquireOwnership, so that a crashed
+// or offline owner does not block maintenance indefinitely - the first client to notice the
+// expiry takes over. Because it can mutate the lease, it should only be called by code that
+// intends to actually run maintenance as a result, such as RunTask.
+func EnsureOwnership(ctx context.Context, rep repo.RepositoryWriter) (bool, error) {
+	p, err := GetParams(ctx, rep)
+	if err != nil {
+		return false, errors.Wrap(err, "error getting maintenance params")
+	}
+
+	now := rep.Time()
+
+	if p.isOwnedByByThisUser(rep) && !p.OwnerLease.isExpired(now) {
+		return true, nil
+	}
+
+	if !p.OwnerLease.isExpired(now) {
+		// lease not expired: held by someone else, or not held by anyone
+		return false, nil
+	}
+
+	if err := acquireOwnership(ctx, rep, DefaultLeaseTTL, DefaultLeaseHeartbeatInterval, false, now); err != nil {
+		// someone else won the race to re-acquire, or we otherwise aren't eligible
+		return false, nil //nolint:nilerr
+	}
+
+	return true, nil
 }
 
 // GetParams returns repository-wide maintenance parameters.
@@ -94,6 +265,13 @@ func GetParams(ctx context.Context, rep repo.Repository) (*Params, error) {
 		return nil, errors.Wrap(err, "error loading manifest")
 	}
 
+	// synthesize entries from QuickCycle/FullCycle for any task with no stored override, so
+	// callers can always rely on Tasks being fully populated without losing explicit overrides
+	// (including ones that disable a task) from manifests written since per-task schedules existed.
+	p.migrateTasks()
+
+	p.migrateOwner()
+
 	return p, nil
 }
 
@@ -104,6 +282,10 @@ func SetParams(ctx context.Context, rep repo.RepositoryWriter, par *Params) erro
 		return err
 	}
 
+	// keep the deprecated Owner field mirrored so older clients reading this manifest
+	// still see a sensible owner.
+	par.Owner = par.OwnerLease.Owner
+
 	if _, err := rep.PutManifest(ctx, manifestLabels, par); err != nil {
 		return errors.Wrap(err, "put manifest")
 	}
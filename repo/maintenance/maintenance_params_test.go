@@ -0,0 +1,62 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveTaskSchedule_ExplicitDisableOverridesCycleDefault(t *testing.T) {
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: true, Interval: time.Hour},
+		FullCycle:  CycleParams{Enabled: true, Interval: 24 * time.Hour},
+		Tasks: map[string]*TaskSchedule{
+			TaskLogCleanup: {Enabled: false},
+		},
+	}
+
+	got := p.effectiveTaskSchedule(TaskLogCleanup)
+	if got.Enabled {
+		t.Fatalf("expected explicit disable override to stick, got %+v", got)
+	}
+}
+
+func TestEffectiveTaskSchedule_NoOverrideFallsBackToCycle(t *testing.T) {
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: true, Interval: time.Hour},
+		FullCycle:  CycleParams{Enabled: true, Interval: 24 * time.Hour},
+	}
+
+	got := p.effectiveTaskSchedule(TaskSnapshotGC)
+	if !got.Enabled || got.Interval != time.Hour {
+		t.Fatalf("expected quick cycle default, got %+v", got)
+	}
+
+	got = p.effectiveTaskSchedule(TaskBlobGC)
+	if !got.Enabled || got.Interval != 24*time.Hour {
+		t.Fatalf("expected full cycle default, got %+v", got)
+	}
+}
+
+func TestMigrateTasks_PreservesExplicitDisableAndFillsRest(t *testing.T) {
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: true, Interval: time.Hour},
+		FullCycle:  CycleParams{Enabled: true, Interval: 24 * time.Hour},
+		Tasks: map[string]*TaskSchedule{
+			TaskLogCleanup: {Enabled: false},
+		},
+	}
+
+	p.migrateTasks()
+
+	if ts := p.Tasks[TaskLogCleanup]; ts == nil || ts.Enabled {
+		t.Fatalf("expected %s to remain disabled after migration, got %+v", TaskLogCleanup, ts)
+	}
+
+	if ts := p.Tasks[TaskSnapshotGC]; ts == nil || !ts.Enabled {
+		t.Fatalf("expected %s to be synthesized as enabled, got %+v", TaskSnapshotGC, ts)
+	}
+
+	if ts := p.Tasks[TaskBlobGC]; ts == nil || !ts.Enabled || ts.Interval != 24*time.Hour {
+		t.Fatalf("expected %s to be synthesized from full cycle, got %+v", TaskBlobGC, ts)
+	}
+}
@@ -0,0 +1,122 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+)
+
+// TaskFunc performs the work of a single maintenance task (snapshot-gc, blob-gc, etc.).
+type TaskFunc func(ctx context.Context, rep repo.RepositoryWriter) error
+
+// RunTask runs a single named maintenance task, provided the current client owns
+// maintenance, and records its outcome in the Status manifest. It is the single place
+// through which individual tasks should be invoked - by the periodic runner loop below, by
+// the CLI, or by an embedder's own scheduler - so that Status stays accurate regardless of
+// caller.
+func RunTask(ctx context.Context, rep repo.RepositoryWriter, taskName string, run TaskFunc) error {
+	sink := eventSinkFor(rep)
+
+	owned, err := EnsureOwnership(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error checking maintenance ownership")
+	}
+
+	if !owned {
+		now := rep.Time()
+		return RecordTaskResult(ctx, rep, taskName, now, now, TaskOutcomeSkippedNotOwner, nil, time.Time{})
+	}
+
+	sink.OnTaskStart(taskName)
+
+	// Stash the sink in ctx so that task implementations further down the call stack -
+	// notably blob-compaction and snapshot-gc, which are what OnBlobRewritten and
+	// OnSnapshotDeleted exist to report on - can reach it without needing rep's identity to
+	// look it up via eventSinkFor themselves.
+	taskCtx := WithEventSink(ctx, sink)
+
+	start := rep.Time()
+	taskErr := run(taskCtx, rep)
+	finish := rep.Time()
+
+	sink.OnTaskFinish(taskName, finish.Sub(start), taskErr)
+
+	outcome := TaskOutcomeSuccess
+	if taskErr != nil {
+		outcome = TaskOutcomeFailed
+	}
+
+	if err := RecordTaskResult(ctx, rep, taskName, start, finish, outcome, taskErr, time.Time{}); err != nil {
+		return errors.Wrap(err, "error recording maintenance task result")
+	}
+
+	return taskErr
+}
+
+// RunDueTasks runs whichever configured tasks are due, earliest first, delegating the
+// actual work of each named task to taskRunners. This is what the periodic maintenance loop
+// (or anything scheduling maintenance on its behalf) should call instead of reimplementing
+// quick/full cycle alternation, and it keeps Status.Phase up to date around the run.
+func RunDueTasks(ctx context.Context, rep repo.RepositoryWriter, taskRunners map[string]TaskFunc) (resultErr error) {
+	sink := eventSinkFor(rep)
+
+	sink.OnCycleStart("scheduled")
+	defer func() { sink.OnCycleEnd("scheduled", resultErr) }()
+
+	p, err := GetParams(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error getting maintenance params")
+	}
+
+	status, err := GetStatus(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error getting maintenance status")
+	}
+
+	lastRun := map[string]time.Time{}
+	for name, ts := range status.Tasks {
+		lastRun[name] = ts.LastFinishTime
+	}
+
+	if err := SetPhase(ctx, rep, PhaseScheduled); err != nil {
+		return errors.Wrap(err, "error updating maintenance phase")
+	}
+
+	for {
+		now := rep.Time()
+
+		due, err := p.NextDueTask(now, lastRun)
+		if err != nil {
+			return errors.Wrap(err, "error computing next due maintenance task")
+		}
+
+		if due == nil || due.DueTime.After(now) {
+			break
+		}
+
+		run, ok := taskRunners[due.TaskName]
+		if !ok {
+			// A task configured/scheduled but with no registered runner shouldn't stop the
+			// rest of the cycle from proceeding - skip it, but still advance lastRun so it
+			// doesn't keep winning "earliest due" on every future iteration of this loop and
+			// starving every other due task.
+			lastRun[due.TaskName] = now
+			continue
+		}
+
+		if err := SetPhase(ctx, rep, PhaseRunning); err != nil {
+			return errors.Wrap(err, "error updating maintenance phase")
+		}
+
+		if err := RunTask(ctx, rep, due.TaskName, run); err != nil {
+			return errors.Wrapf(err, "error running maintenance task %q", due.TaskName)
+		}
+
+		lastRun[due.TaskName] = rep.Time()
+	}
+
+	return SetPhase(ctx, rep, PhaseIdle)
+}
@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// nolint:gochecknoglobals
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// NextRunTime returns the next time, strictly after 'after', at which a task with this
+// schedule is due to run. It returns the zero time if the schedule is disabled.
+func (t TaskSchedule) NextRunTime(after time.Time) (time.Time, error) {
+	if !t.Enabled {
+		return time.Time{}, nil
+	}
+
+	next, err := t.nextUnjittered(after)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if next.IsZero() || t.Jitter <= 0 {
+		return next, nil
+	}
+
+	return next.Add(time.Duration(rand.Int63n(int64(t.Jitter)))), nil //nolint:gosec
+}
+
+func (t TaskSchedule) nextUnjittered(after time.Time) (time.Time, error) {
+	if t.Cron != "" {
+		sched, err := cronParser.Parse(t.Cron)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid cron expression %q", t.Cron)
+		}
+
+		return sched.Next(after), nil
+	}
+
+	if t.Interval <= 0 {
+		return time.Time{}, nil
+	}
+
+	return after.Add(t.Interval), nil
+}
+
+// DueTask identifies the next task that is due to run, and when.
+type DueTask struct {
+	TaskName string
+	DueTime  time.Time
+}
+
+// NextDueTask returns the task, among all tasks in Params and lastRun, that is due to run
+// soonest, replacing the previous fixed quick/full cycle alternation with a schedule that
+// picks whichever configured task is due earliest across every schedule.
+//
+// lastRun maps task name to the last time it finished running (or its zero value if it has
+// never run), and is used as the basis for every task: an Interval task is due Interval
+// after its last run, a Cron task at the first cron time after its last run.
+func (p *Params) NextDueTask(now time.Time, lastRun map[string]time.Time) (*DueTask, error) {
+	var best *DueTask
+
+	for _, name := range p.allTaskNames() {
+		ts := p.effectiveTaskSchedule(name)
+		if !ts.Enabled {
+			continue
+		}
+
+		// Both Interval and Cron schedules are based on the last run time, so a cron time
+		// that has passed since then makes the task due. A task that has never run before
+		// has no entry in lastRun - treat it as due immediately rather than deferring its
+		// first run by a full Interval (or to the next cron time) from now.
+		due, err := ts.NextRunTime(lastRun[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "error computing schedule for task %q", name)
+		}
+
+		if due.IsZero() {
+			continue
+		}
+
+		if best == nil || due.Before(best.DueTime) {
+			best = &DueTask{TaskName: name, DueTime: due}
+		}
+	}
+
+	return best, nil
+}
@@ -0,0 +1,144 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskSchedule_NextRunTime_Disabled(t *testing.T) {
+	ts := TaskSchedule{Enabled: false, Interval: time.Hour}
+
+	got, err := ts.NextRunTime(time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.IsZero() {
+		t.Fatalf("expected zero time for disabled schedule, got %v", got)
+	}
+}
+
+func TestTaskSchedule_NextRunTime_Interval(t *testing.T) {
+	after := time.Unix(1000, 0)
+	ts := TaskSchedule{Enabled: true, Interval: time.Hour}
+
+	got, err := ts.NextRunTime(after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := after.Add(time.Hour); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTaskSchedule_NextRunTime_IntervalWithJitter(t *testing.T) {
+	after := time.Unix(1000, 0)
+	ts := TaskSchedule{Enabled: true, Interval: time.Hour, Jitter: time.Minute}
+
+	base := after.Add(time.Hour)
+
+	for i := 0; i < 20; i++ {
+		got, err := ts.NextRunTime(after)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Before(base) || got.After(base.Add(time.Minute)) {
+			t.Fatalf("got %v, want within [%v, %v]", got, base, base.Add(time.Minute))
+		}
+	}
+}
+
+func TestTaskSchedule_NextRunTime_Cron(t *testing.T) {
+	// 2024-01-01 00:00:00 UTC is a Monday.
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := TaskSchedule{Enabled: true, Cron: "0 2 * * 0"} // Sundays at 02:00
+
+	got, err := ts.NextRunTime(after)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2024, 1, 7, 2, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestTaskSchedule_NextRunTime_InvalidCron(t *testing.T) {
+	ts := TaskSchedule{Enabled: true, Cron: "not a cron expression"}
+
+	if _, err := ts.NextRunTime(time.Unix(0, 0)); err == nil {
+		t.Fatal("expected error for invalid cron expression")
+	}
+}
+
+func TestNextDueTask_PicksEarliestAcrossSchedules(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: true, Interval: time.Hour},
+		FullCycle:  CycleParams{Enabled: true, Interval: 24 * time.Hour},
+		Tasks: map[string]*TaskSchedule{
+			TaskBlobGC: {Enabled: true, Interval: time.Minute},
+		},
+	}
+
+	due, err := p.NextDueTask(now, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if due == nil || due.TaskName != TaskBlobGC {
+		t.Fatalf("expected %s to be due soonest, got %+v", TaskBlobGC, due)
+	}
+}
+
+func TestNextDueTask_NeverRunTaskIsImmediatelyDue(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: true, Interval: 24 * time.Hour},
+		FullCycle:  CycleParams{Enabled: false, Interval: 24 * time.Hour},
+		Tasks: map[string]*TaskSchedule{
+			TaskBlobGC: {Enabled: true, Interval: 24 * time.Hour},
+		},
+	}
+
+	// Every other quick-cycle task already ran recently, so only TaskBlobGC - which has no
+	// lastRun entry, meaning it has never run - should be immediately due.
+	due, err := p.NextDueTask(now, map[string]time.Time{
+		TaskSnapshotGC: now,
+		TaskLogCleanup: now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if due == nil || due.TaskName != TaskBlobGC {
+		t.Fatalf("expected never-run %s to be immediately due, got %+v", TaskBlobGC, due)
+	}
+
+	if !due.DueTime.Before(now) {
+		t.Fatalf("expected due time %v to be before now (%v)", due.DueTime, now)
+	}
+}
+
+func TestNextDueTask_SkipsDisabledTasks(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	p := &Params{
+		QuickCycle: CycleParams{Enabled: false, Interval: time.Hour},
+		FullCycle:  CycleParams{Enabled: false, Interval: time.Hour},
+	}
+
+	due, err := p.NextDueTask(now, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if due != nil {
+		t.Fatalf("expected no due task when everything is disabled, got %+v", due)
+	}
+}
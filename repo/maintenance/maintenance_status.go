@@ -0,0 +1,217 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+// nolint:gochecknoglobals
+var statusManifestLabels = map[string]string{
+	"type": "maintenance-status",
+}
+
+// Phase describes the overall state of the maintenance subsystem for a repository.
+type Phase string
+
+// Supported Phase values.
+const (
+	PhaseIdle      Phase = "idle"
+	PhaseScheduled Phase = "scheduled"
+	PhaseRunning   Phase = "running"
+	PhaseFailed    Phase = "failed"
+	PhaseNotOwned  Phase = "not-owned"
+)
+
+// TaskOutcome describes the result of the most recently completed run of a maintenance task.
+type TaskOutcome string
+
+// Supported TaskOutcome values.
+const (
+	TaskOutcomeSuccess         TaskOutcome = "success"
+	TaskOutcomeFailed          TaskOutcome = "failed"
+	TaskOutcomeSkippedNotOwner TaskOutcome = "skipped-not-owner"
+)
+
+// TaskStatus records the outcome of the most recent run of a single maintenance task.
+type TaskStatus struct {
+	TaskName string `json:"taskName"`
+
+	LastStartTime  time.Time `json:"lastStartTime"`
+	LastFinishTime time.Time `json:"lastFinishTime"`
+
+	LastOutcome TaskOutcome `json:"lastOutcome"`
+	LastError   string      `json:"lastError,omitempty"`
+
+	NextRunTime time.Time `json:"nextRunTime,omitempty"`
+}
+
+// Status is a JSON-serialized snapshot of the current maintenance state stored in a repository.
+// It is updated on every maintenance cycle so that operators and automation can poll it without
+// scraping repository blobs directly.
+//
+// NOTE: this only covers the repository-side data model. Surfacing it through the KopiaUI/API
+// server is a separate, currently unimplemented piece of work - there is no server code in this
+// package, and none is added here. Anything that wants "wait for Idle before starting a
+// snapshot" today has to call GetStatus directly against the repository.
+type Status struct {
+	Phase Phase `json:"phase"`
+
+	Tasks map[string]*TaskStatus `json:"tasks"`
+}
+
+// DefaultStatus returns an empty status: phase Idle and no per-task entries.
+func DefaultStatus() Status {
+	return Status{
+		Phase: PhaseIdle,
+		Tasks: map[string]*TaskStatus{},
+	}
+}
+
+// GetStatus returns the current maintenance status for the repository.
+func GetStatus(ctx context.Context, rep repo.Repository) (*Status, error) {
+	md, err := rep.FindManifests(ctx, statusManifestLabels)
+	if err != nil {
+		return nil, errors.Wrap(err, "error looking for maintenance status manifest")
+	}
+
+	if len(md) == 0 {
+		s := DefaultStatus()
+		return &s, nil
+	}
+
+	// arbitrarily pick the latest manifest ID to return in case there's more than one
+	// this can happen when two repository clients independently record a result at
+	// approximately the same time; it should not really matter which one we pick since
+	// the next RecordTaskResult() will reconcile them.
+	manifestID := manifest.PickLatestID(md)
+
+	s := &Status{}
+	if _, err := rep.GetManifest(ctx, manifestID, s); err != nil {
+		return nil, errors.Wrap(err, "error loading maintenance status manifest")
+	}
+
+	if s.Tasks == nil {
+		s.Tasks = map[string]*TaskStatus{}
+	}
+
+	return s, nil
+}
+
+// RecordTaskResult updates the status manifest with the outcome of a single task run and
+// recomputes the overall phase. It follows the same read-modify-write-then-delete-stale
+// pattern used by SetParams to tolerate concurrent writers.
+func RecordTaskResult(ctx context.Context, rep repo.RepositoryWriter, taskName string, startTime, finishTime time.Time, outcome TaskOutcome, taskErr error, nextRunTime time.Time) error {
+	md, err := rep.FindManifests(ctx, statusManifestLabels)
+	if err != nil {
+		return errors.Wrap(err, "error looking for maintenance status manifest")
+	}
+
+	s := DefaultStatus()
+
+	if len(md) > 0 {
+		if _, err := rep.GetManifest(ctx, manifest.PickLatestID(md), &s); err != nil {
+			return errors.Wrap(err, "error loading maintenance status manifest")
+		}
+	}
+
+	if s.Tasks == nil {
+		s.Tasks = map[string]*TaskStatus{}
+	}
+
+	ts := &TaskStatus{
+		TaskName:       taskName,
+		LastStartTime:  startTime,
+		LastFinishTime: finishTime,
+		LastOutcome:    outcome,
+		NextRunTime:    nextRunTime,
+	}
+
+	if taskErr != nil {
+		ts.LastError = taskErr.Error()
+	}
+
+	s.Tasks[taskName] = ts
+	// computePhase takes the phase already stored in the manifest (s.Phase, as set by the
+	// last SetPhase call) as a starting point, so a single task finishing successfully
+	// mid-cycle doesn't stomp Running/Scheduled back to Idle while other due tasks are
+	// still queued - only a failure or an ownership loss should override it here. The
+	// eventual transition back to Idle is the runner's job, via an explicit SetPhase once
+	// the whole cycle is done.
+	s.Phase = computePhase(s.Phase, s.Tasks)
+
+	if _, err := rep.PutManifest(ctx, statusManifestLabels, &s); err != nil {
+		return errors.Wrap(err, "error writing maintenance status manifest")
+	}
+
+	for _, m := range md {
+		if err := rep.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrap(err, "error deleting stale maintenance status manifest")
+		}
+	}
+
+	return nil
+}
+
+// SetPhase records the overall maintenance phase, e.g. when a cycle starts or finishes running
+// but before any individual task outcome is known.
+func SetPhase(ctx context.Context, rep repo.RepositoryWriter, phase Phase) error {
+	md, err := rep.FindManifests(ctx, statusManifestLabels)
+	if err != nil {
+		return errors.Wrap(err, "error looking for maintenance status manifest")
+	}
+
+	s := DefaultStatus()
+
+	if len(md) > 0 {
+		if _, err := rep.GetManifest(ctx, manifest.PickLatestID(md), &s); err != nil {
+			return errors.Wrap(err, "error loading maintenance status manifest")
+		}
+	}
+
+	s.Phase = phase
+
+	if _, err := rep.PutManifest(ctx, statusManifestLabels, &s); err != nil {
+		return errors.Wrap(err, "error writing maintenance status manifest")
+	}
+
+	for _, m := range md {
+		if err := rep.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrap(err, "error deleting stale maintenance status manifest")
+		}
+	}
+
+	return nil
+}
+
+// computePhase derives the overall phase from individual task statuses: any failure
+// dominates, then any task skipped because this client isn't the owner, otherwise the
+// current phase is preserved if it reflects a cycle still in progress (Scheduled/Running) -
+// only SetPhase is allowed to transition it back to Idle once the whole cycle is done.
+func computePhase(currentPhase Phase, tasks map[string]*TaskStatus) Phase {
+	sawNotOwned := false
+
+	for _, t := range tasks {
+		if t.LastOutcome == TaskOutcomeFailed {
+			return PhaseFailed
+		}
+
+		if t.LastOutcome == TaskOutcomeSkippedNotOwner {
+			sawNotOwned = true
+		}
+	}
+
+	if sawNotOwned {
+		return PhaseNotOwned
+	}
+
+	if currentPhase == PhaseScheduled || currentPhase == PhaseRunning {
+		return currentPhase
+	}
+
+	return PhaseIdle
+}
@@ -0,0 +1,41 @@
+package maintenance
+
+import "testing"
+
+func TestComputePhase(t *testing.T) {
+	cases := []struct {
+		name    string
+		current Phase
+		tasks   map[string]*TaskStatus
+		want    Phase
+	}{
+		{"no tasks, idle before", PhaseIdle, map[string]*TaskStatus{}, PhaseIdle},
+		{"all success, idle before", PhaseIdle, map[string]*TaskStatus{
+			TaskSnapshotGC: {LastOutcome: TaskOutcomeSuccess},
+		}, PhaseIdle},
+		{"not owned", PhaseIdle, map[string]*TaskStatus{
+			TaskSnapshotGC: {LastOutcome: TaskOutcomeSkippedNotOwner},
+		}, PhaseNotOwned},
+		{"failed dominates not owned", PhaseIdle, map[string]*TaskStatus{
+			TaskSnapshotGC: {LastOutcome: TaskOutcomeSkippedNotOwner},
+			TaskBlobGC:     {LastOutcome: TaskOutcomeFailed},
+		}, PhaseFailed},
+		{"success mid-cycle preserves running", PhaseRunning, map[string]*TaskStatus{
+			TaskSnapshotGC: {LastOutcome: TaskOutcomeSuccess},
+		}, PhaseRunning},
+		{"success mid-cycle preserves scheduled", PhaseScheduled, map[string]*TaskStatus{
+			TaskSnapshotGC: {LastOutcome: TaskOutcomeSuccess},
+		}, PhaseScheduled},
+		{"failure overrides running", PhaseRunning, map[string]*TaskStatus{
+			TaskBlobGC: {LastOutcome: TaskOutcomeFailed},
+		}, PhaseFailed},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := computePhase(tc.current, tc.tasks); got != tc.want {
+				t.Fatalf("computePhase() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}